@@ -0,0 +1,145 @@
+package c2cx
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// defaultMaxBackoff caps per-symbol exponential backoff when
+// Client.MaxBackoff is unset
+const defaultMaxBackoff = 2 * time.Minute
+
+// jitterFraction is the maximum fraction of the polling interval added
+// or subtracted as jitter, to avoid a thundering herd against the c2cx
+// REST endpoint
+const jitterFraction = 0.2
+
+// SymbolMetrics reports the health of a single symbol's polling loop,
+// as returned by Client.Metrics
+type SymbolMetrics struct {
+	Success        int64
+	Failure        int64
+	LastUpdate     time.Time
+	CurrentBackoff time.Duration
+}
+
+// Metrics returns a snapshot of per-symbol success/failure counts, last
+// update time, and current backoff, suitable for a health dashboard
+func (c *Client) Metrics() map[string]SymbolMetrics {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+
+	out := make(map[string]SymbolMetrics, len(c.metrics))
+	for sym, m := range c.metrics {
+		out[sym] = m
+	}
+	return out
+}
+
+func (c *Client) recordSuccess(symbol string, backoff time.Duration) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+	m := c.metrics[symbol]
+	m.Success++
+	m.LastUpdate = time.Now()
+	m.CurrentBackoff = backoff
+	c.metrics[symbol] = m
+}
+
+func (c *Client) recordFailure(symbol string, backoff time.Duration) {
+	c.metricsMu.Lock()
+	defer c.metricsMu.Unlock()
+	m := c.metrics[symbol]
+	m.Failure++
+	m.CurrentBackoff = backoff
+	c.metrics[symbol] = m
+}
+
+func (c *Client) maxBackoff() time.Duration {
+	if c.MaxBackoff > 0 {
+		return c.MaxBackoff
+	}
+	return defaultMaxBackoff
+}
+
+// jitter randomizes d by +/- jitterFraction, so symbols polling on the
+// same base interval don't all hit the REST endpoint at once
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	delta := float64(d) * jitterFraction
+	offset := (rand.Float64()*2 - 1) * delta
+	return d + time.Duration(offset)
+}
+
+// nextBackoff doubles the previous backoff, starting from base, and
+// caps it at max
+func nextBackoff(prev, base, max time.Duration) time.Duration {
+	if prev <= 0 {
+		prev = base
+	} else {
+		prev *= 2
+	}
+	if prev > max {
+		prev = max
+	}
+	return prev
+}
+
+// Update runs an adaptive, per-symbol polling loop until Stop is
+// signaled. Each symbol gets its own goroutine polling at the shared
+// RefreshInterval, with randomized jitter and its own exponential
+// backoff on errors, so a
+// single struggling symbol does not slow down the others. Outbound
+// calls across all symbols are bounded by Client.RateLimiter, if set.
+func (c *Client) Update() {
+	c.metricsMu.Lock()
+	if c.metrics == nil {
+		c.metrics = make(map[string]SymbolMetrics, len(allowed))
+	}
+	c.metricsMu.Unlock()
+
+	c.rehydrate()
+
+	// c.Stop delivers to a single receiver, so fan it out to every
+	// per-symbol goroutine via a closed channel
+	done := make(chan struct{})
+	go func() {
+		<-c.Stop
+		close(done)
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(len(allowed))
+	for _, sym := range allowed {
+		go func(symbol string) {
+			defer wg.Done()
+			c.pollSymbol(symbol, done)
+		}(sym)
+	}
+	wg.Wait()
+}
+
+func (c *Client) pollSymbol(symbol string, done <-chan struct{}) {
+	var backoff time.Duration
+	for {
+		wait := jitter(c.RefreshInterval + backoff)
+		timer := time.NewTimer(wait)
+		select {
+		case <-done:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if err := c.checkUpdate(symbol); err != nil {
+			backoff = nextBackoff(backoff, c.RefreshInterval, c.maxBackoff())
+			c.recordFailure(symbol, backoff)
+			continue
+		}
+		backoff = 0
+		c.recordSuccess(symbol, backoff)
+	}
+}
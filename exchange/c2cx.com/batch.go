@@ -0,0 +1,161 @@
+package c2cx
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// defaultBatchConcurrency bounds the number of in-flight order
+// submissions for BatchPlaceOrders when the caller has no opinion
+const defaultBatchConcurrency = 8
+
+// OrderRequest describes a single buy or sell order to submit as part
+// of a batch
+type OrderRequest struct {
+	Symbol string
+	Side   string
+	Price  float64
+	Amount float64
+}
+
+// RetryPolicy controls how BatchRetryPlaceOrders retries failed orders
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts per order, including
+	// the first one
+	MaxAttempts int
+	// BaseDelay is the backoff delay after the first failed attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential backoff delay
+	MaxDelay time.Duration
+	// Concurrency bounds the number of in-flight submissions
+	Concurrency int
+}
+
+// DefaultRetryPolicy is used by BatchRetryPlaceOrders when the caller
+// passes the zero value
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 5,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Concurrency: defaultBatchConcurrency,
+}
+
+// BatchPlaceOrders submits reqs concurrently, bounded by
+// defaultBatchConcurrency, and returns the orderID and error for each
+// request in input order. It does not retry failed submissions, use
+// BatchRetryPlaceOrders for that.
+func (c *Client) BatchPlaceOrders(ctx context.Context, reqs []OrderRequest) ([]int, []error) {
+	return c.BatchRetryPlaceOrders(ctx, reqs, RetryPolicy{
+		MaxAttempts: 1,
+		Concurrency: defaultBatchConcurrency,
+	})
+}
+
+// BatchRetryPlaceOrders submits reqs concurrently, bounded by
+// policy.Concurrency, and retries only the failed subset with
+// exponential backoff up to policy.MaxAttempts. Terminal errors, such
+// as authentication or validation failures, are not retried. Results
+// are returned in input order; each successful submission registers
+// with Tracker.NewOrder the same way Buy/Sell do.
+func (c *Client) BatchRetryPlaceOrders(ctx context.Context, reqs []OrderRequest, policy RetryPolicy) ([]int, []error) {
+	if policy.MaxAttempts <= 0 {
+		policy = DefaultRetryPolicy
+	}
+	concurrency := policy.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultBatchConcurrency
+	}
+
+	orderIDs := make([]int, len(reqs))
+	errs := make([]error, len(reqs))
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	wg.Add(len(reqs))
+	for i, req := range reqs {
+		go func(i int, req OrderRequest) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			orderIDs[i], errs[i] = c.placeWithRetry(ctx, req, policy)
+		}(i, req)
+	}
+	wg.Wait()
+
+	return orderIDs, errs
+}
+
+func (c *Client) placeWithRetry(ctx context.Context, req OrderRequest, policy RetryPolicy) (int, error) {
+	var orderID int
+	var err error
+	for attempt := 0; attempt < policy.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			delay := backoffDelay(attempt, policy.BaseDelay, policy.MaxDelay)
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+
+		switch strings.ToLower(req.Side) {
+		case "buy":
+			orderID, err = c.Buy(req.Symbol, req.Price, req.Amount)
+		case "sell":
+			orderID, err = c.Sell(req.Symbol, req.Price, req.Amount)
+		default:
+			return 0, errors.Errorf("c2cx: unknown side %q", req.Side)
+		}
+
+		if err == nil {
+			return orderID, nil
+		}
+		if !isRetryableError(err) {
+			return 0, err
+		}
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+	}
+	return 0, err
+}
+
+// isRetryableError reports whether err likely resulted from a transient
+// condition, as opposed to a terminal auth or validation failure that
+// will fail again on every retry.
+func isRetryableError(err error) bool {
+	msg := strings.ToLower(errors.Cause(err).Error())
+	switch {
+	case strings.Contains(msg, "auth"),
+		strings.Contains(msg, "signature"),
+		strings.Contains(msg, "permission"),
+		strings.Contains(msg, "invalid"),
+		strings.Contains(msg, "does not found"):
+		return false
+	}
+	return true
+}
+
+func backoffDelay(attempt int, base, max time.Duration) time.Duration {
+	if base <= 0 {
+		base = DefaultRetryPolicy.BaseDelay
+	}
+	if max <= 0 {
+		max = DefaultRetryPolicy.MaxDelay
+	}
+	delay := base * time.Duration(math.Pow(2, float64(attempt-1)))
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
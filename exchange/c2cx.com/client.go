@@ -1,6 +1,7 @@
 package c2cx
 
 import (
+	"context"
 	"time"
 
 	"strings"
@@ -9,7 +10,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/uberfurrer/tradebot/exchange"
-	"github.com/uberfurrer/tradebot/logger"
+	"golang.org/x/time/rate"
 )
 
 // Client implements exchange.Client interface
@@ -17,8 +18,17 @@ import (
 type Client struct {
 	// Key and Secret needs for creating and accessing orders, update them
 	// You may use Client without it for tracking OrderBook
-	Key, Secret     string
+	Key, Secret string
+	// RefreshInterval is the base polling interval for each symbol
+	// actual interval is jittered and grows with backoff on errors, see Metrics
 	RefreshInterval time.Duration
+	// MaxBackoff caps the per-symbol exponential backoff applied after
+	// consecutive errors, 0 uses defaultMaxBackoff
+	MaxBackoff time.Duration
+
+	// RateLimiter bounds the rate of outbound calls to the c2cx REST API
+	// shared across all symbols, nil disables rate limiting
+	RateLimiter *rate.Limiter
 
 	// Tracker provides provides functionality for tracking orders
 	// if Tracker == nil then orders does not tracked and Client will be update only OrderBook directly
@@ -34,8 +44,17 @@ type Client struct {
 	// After sending to this, you need to restart Client.Update()
 	Stop chan struct{}
 
-	prevUpdate time.Time
-	sem        chan struct{}
+	// Logger receives structured events from the update loop
+	// if Logger == nil, logging is disabled
+	Logger Logger
+
+	// Store persists submitted orders, status transitions and executed
+	// trades, so Update can rehydrate Tracker across restarts
+	// if Store == nil, nothing is persisted
+	Store Store
+
+	metricsMu sync.Mutex
+	metrics   map[string]SymbolMetrics
 }
 
 // Cancel cancels order with given orderID
@@ -95,6 +114,7 @@ func (c *Client) Buy(symbol string, price, amount float64) (orderID int, err err
 		return
 	}
 	c.Tracker.NewOrder(symbol, exchange.ActionBuy, exchange.StatusSubmitted, orderID, amount, price)
+	c.persistOrder(orderID)
 	return
 }
 
@@ -110,6 +130,7 @@ func (c *Client) Sell(symbol string, price, amount float64) (orderID int, err er
 		return
 	}
 	c.Tracker.NewOrder(symbol, exchange.ActionSell, exchange.StatusSubmitted, orderID, amount, price)
+	c.persistOrder(orderID)
 	return
 }
 
@@ -149,71 +170,123 @@ func (c *Client) GetBalance(currency string) (string, error) {
 	return "", errors.Errorf("currency %s does not found", currency)
 }
 
-func (c *Client) checkUpdate() {
-	if c.OrderBookTracker != nil {
-		// runs goroutine for each market and wait them
-		go func() {
-			c.sem <- struct{}{}
-			var wg sync.WaitGroup
-			wg.Add(len(allowed))
-			for _, v := range allowed {
-				go func(sym string, w *sync.WaitGroup) {
-					defer w.Done()
-					orders, err := GetOrderBook(sym)
-					if err != nil {
-						//log.Printf("c2cx: update orderbook error: %s, %s", err.Error(), sym)
-						return
-					}
-					c.OrderBookTracker.UpdateSym(sym, orders.Bids, orders.Asks)
-					return
-				}(v, &wg)
-			}
-			wg.Wait()
-			<-c.sem
-		}()
+// checkUpdate fetches the order book and, if Tracker is set, every
+// tracked order status for a single symbol, and returns the last fetch
+// error, if any. pollSymbol in adaptive.go schedules it per symbol and
+// records the outcome in Metrics.
+func (c *Client) checkUpdate(symbol string) error {
+	var failed error
 
+	if c.OrderBookTracker != nil {
+		if err := c.waitRateLimit(); err != nil {
+			return err
+		}
+		orders, err := GetOrderBook(symbol)
+		if err != nil {
+			c.logger().Warnf("c2cx: update orderbook failed", "symbol", symbol, "error", err)
+			failed = err
+		} else {
+			c.OrderBookTracker.UpdateSym(symbol, orders.Bids, orders.Asks)
+		}
 	}
+
 	if c.Tracker != nil {
-		var wg sync.WaitGroup
-		wg.Add(len(allowed) * len(Statusees))
-		for _, sym := range allowed {
-			for s := range Statusees {
-				go func(symbol, status string, w *sync.WaitGroup) {
-					defer w.Done()
-					orders, err := GetOrderByStatus(c.Key, c.Secret, symbol, status, -1)
-					if err != nil {
-						logger.Warningf("c2cx: update order info failed %s", err)
-						return
-					}
-					for _, order := range orders {
-						var accepted = unixToTime(order.CreateDate)
-						switch status {
-						case exchange.StatusOpened, exchange.StatusPartial:
-							c.Tracker.UpdateOrderDetails(order.OrderID, symbol, &accepted)
-						case exchange.StatusCancelled:
-							c.Tracker.Cancel(order.OrderID)
-						case exchange.StatusCompleted:
-							c.Tracker.Complete(order.OrderID, time.Now())
-						}
-					}
-				}(sym, s, &wg)
+		for s := range Statusees {
+			if err := c.waitRateLimit(); err != nil {
+				return err
+			}
+			start := time.Now()
+			orders, err := GetOrderByStatus(c.Key, c.Secret, symbol, s, -1)
+			latency := int64(time.Since(start) / time.Millisecond)
+			if err != nil {
+				c.logger().Warnf("c2cx: update order info failed", "symbol", symbol, "status", s, "latency_ms", latency, "error", err)
+				failed = err
+				continue
 			}
+			for _, order := range orders {
+				var accepted = unixToTime(order.CreateDate)
+				switch s {
+				case exchange.StatusOpened, exchange.StatusPartial:
+					c.Tracker.UpdateOrderDetails(order.OrderID, symbol, &accepted)
+				case exchange.StatusCancelled:
+					c.Tracker.Cancel(order.OrderID)
+					c.persistStatus(order.OrderID)
+				case exchange.StatusCompleted:
+					c.Tracker.Complete(order.OrderID, time.Now())
+					c.persistStatus(order.OrderID)
+					c.persistTrade(symbol, order.OrderID)
+				}
+			}
+			c.logger().Debugf("c2cx: updated orders", "symbol", symbol, "status", s, "count", len(orders), "latency_ms", latency)
 		}
-		wg.Wait()
 	}
+
+	return failed
 }
 
-// Update run updates synchronously
-func (c *Client) Update() {
-	c.sem = make(chan struct{}, 1)
-	t := time.NewTicker(c.RefreshInterval * time.Millisecond)
-	for {
-		select {
-		case <-t.C:
-			c.checkUpdate()
-		case <-c.Stop:
-			t.Stop()
-			return
-		}
+func (c *Client) waitRateLimit() error {
+	if c.RateLimiter == nil {
+		return nil
+	}
+	return c.RateLimiter.Wait(context.Background())
+}
+
+// persistOrder writes orderID's current tracked state to Store, it is
+// a no-op if Store or Tracker is unset
+func (c *Client) persistOrder(orderID int) {
+	if c.Store == nil || c.Tracker == nil {
+		return
+	}
+	order, err := c.Tracker.Get(orderID)
+	if err != nil {
+		c.logger().Errorf("c2cx: persist order failed", "orderID", orderID, "error", err)
+		return
+	}
+	if err := c.Store.SaveOrder(order); err != nil {
+		c.logger().Errorf("c2cx: persist order failed", "orderID", orderID, "error", err)
+	}
+}
+
+// persistStatus writes orderID's current tracked status to Store, it
+// is a no-op if Store or Tracker is unset
+func (c *Client) persistStatus(orderID int) {
+	if c.Store == nil || c.Tracker == nil {
+		return
+	}
+	order, err := c.Tracker.Get(orderID)
+	if err != nil {
+		c.logger().Errorf("c2cx: persist order status failed", "orderID", orderID, "error", err)
+		return
+	}
+	if err := c.Store.UpdateOrderStatus(order); err != nil {
+		c.logger().Errorf("c2cx: persist order status failed", "orderID", orderID, "error", err)
+	}
+}
+
+// QueryTrades returns symbol's trades executed at or after since, read
+// from Store. It returns an error if Store is unset.
+func (c *Client) QueryTrades(symbol string, since time.Time) ([]exchange.Trade, error) {
+	if c.Store == nil {
+		return nil, errors.New("c2cx: QueryTrades requires Store to be set")
+	}
+	symbol, err := normalize(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return c.Store.Trades(symbol, since)
+}
+
+// ClosedOrders returns symbol's completed or cancelled orders whose
+// Accepted time is at or after since (orders with no Accepted time are
+// always included), read from Store. It returns an error if Store is
+// unset.
+func (c *Client) ClosedOrders(symbol string, since time.Time) ([]exchange.OrderInfo, error) {
+	if c.Store == nil {
+		return nil, errors.New("c2cx: ClosedOrders requires Store to be set")
+	}
+	symbol, err := normalize(symbol)
+	if err != nil {
+		return nil, err
 	}
+	return c.Store.ClosedOrders(symbol, since)
 }
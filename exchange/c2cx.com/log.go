@@ -0,0 +1,32 @@
+package c2cx
+
+// Logger is a minimal structured logging interface used by Client to
+// report routine and exceptional events from its update loop. Fields
+// are passed as alternating key/value pairs (e.g. "symbol", sym,
+// "latency_ms", ms), mirroring the logrus/zap "Sugared" convention, so
+// adapters can forward them without reshaping.
+//
+// Routine per-tick activity ("updated N orders") is logged at Debug,
+// while genuine anomalies (auth failures, repeated fetch errors,
+// tracker inconsistencies) are logged at Warn or Error.
+type Logger interface {
+	Debugf(msg string, fields ...interface{})
+	Warnf(msg string, fields ...interface{})
+	Errorf(msg string, fields ...interface{})
+}
+
+// nopLogger discards everything, it is used when Client.Logger is nil
+type nopLogger struct{}
+
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+func (nopLogger) Errorf(string, ...interface{}) {}
+
+// logger returns c.Logger, falling back to a no-op implementation so
+// callers never need a nil check
+func (c *Client) logger() Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return nopLogger{}
+}
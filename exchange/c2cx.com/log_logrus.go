@@ -0,0 +1,39 @@
+package c2cx
+
+import "github.com/sirupsen/logrus"
+
+// LogrusLogger adapts a *logrus.Entry (or *logrus.Logger, via
+// logrus.NewEntry) to the Logger interface, so it can be assigned
+// directly to Client.Logger
+type LogrusLogger struct {
+	Entry *logrus.Entry
+}
+
+// NewLogrusLogger wraps l for use as a Client.Logger
+func NewLogrusLogger(l *logrus.Logger) *LogrusLogger {
+	return &LogrusLogger{Entry: logrus.NewEntry(l)}
+}
+
+func (l *LogrusLogger) Debugf(msg string, fields ...interface{}) {
+	l.Entry.WithFields(fieldsToLogrus(fields)).Debug(msg)
+}
+
+func (l *LogrusLogger) Warnf(msg string, fields ...interface{}) {
+	l.Entry.WithFields(fieldsToLogrus(fields)).Warn(msg)
+}
+
+func (l *LogrusLogger) Errorf(msg string, fields ...interface{}) {
+	l.Entry.WithFields(fieldsToLogrus(fields)).Error(msg)
+}
+
+func fieldsToLogrus(fields []interface{}) logrus.Fields {
+	f := make(logrus.Fields, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok {
+			continue
+		}
+		f[key] = fields[i+1]
+	}
+	return f
+}
@@ -0,0 +1,26 @@
+package c2cx
+
+import "go.uber.org/zap"
+
+// ZapLogger adapts a *zap.SugaredLogger to the Logger interface, so it
+// can be assigned directly to Client.Logger
+type ZapLogger struct {
+	Sugar *zap.SugaredLogger
+}
+
+// NewZapLogger wraps l for use as a Client.Logger
+func NewZapLogger(l *zap.Logger) *ZapLogger {
+	return &ZapLogger{Sugar: l.Sugar()}
+}
+
+func (l *ZapLogger) Debugf(msg string, fields ...interface{}) {
+	l.Sugar.Debugw(msg, fields...)
+}
+
+func (l *ZapLogger) Warnf(msg string, fields ...interface{}) {
+	l.Sugar.Warnw(msg, fields...)
+}
+
+func (l *ZapLogger) Errorf(msg string, fields ...interface{}) {
+	l.Sugar.Errorw(msg, fields...)
+}
@@ -0,0 +1,178 @@
+package c2cx
+
+import (
+	"sync"
+	"time"
+
+	"github.com/uberfurrer/tradebot/exchange"
+)
+
+// Store persists submitted orders, status transitions and executed
+// trades so Client can rehydrate Tracker and serve trade/order history
+// across process restarts. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// SaveOrder persists a newly submitted order
+	SaveOrder(order exchange.OrderInfo) error
+	// UpdateOrderStatus persists order's current status, overwriting
+	// whatever was previously stored for its OrderID
+	UpdateOrderStatus(order exchange.OrderInfo) error
+	// SaveTrade persists an executed trade for symbol
+	SaveTrade(symbol string, trade exchange.Trade) error
+
+	// Orders returns every order known to the store, used by
+	// Client.Update to rehydrate Tracker on startup
+	Orders() ([]exchange.OrderInfo, error)
+	// ClosedOrders returns symbol's completed or cancelled orders whose
+	// Accepted time is at or after since; orders with no Accepted time
+	// are always included
+	ClosedOrders(symbol string, since time.Time) ([]exchange.OrderInfo, error)
+	// Trades returns symbol's trades executed at or after since
+	Trades(symbol string, since time.Time) ([]exchange.Trade, error)
+
+	// Close releases any resources held by the store
+	Close() error
+}
+
+// MemoryStore is an in-memory Store, it does not survive a process
+// restart and exists mainly for tests and for callers that only need
+// the Store-backed query methods within a single run
+type MemoryStore struct {
+	mu     sync.Mutex
+	orders map[int]exchange.OrderInfo
+	trades map[string][]exchange.Trade
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orders: make(map[int]exchange.OrderInfo),
+		trades: make(map[string][]exchange.Trade),
+	}
+}
+
+func (m *MemoryStore) SaveOrder(order exchange.OrderInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[order.OrderID] = order
+	return nil
+}
+
+func (m *MemoryStore) UpdateOrderStatus(order exchange.OrderInfo) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.orders[order.OrderID] = order
+	return nil
+}
+
+func (m *MemoryStore) SaveTrade(symbol string, trade exchange.Trade) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.trades[symbol] = append(m.trades[symbol], trade)
+	return nil
+}
+
+func (m *MemoryStore) Orders() ([]exchange.OrderInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]exchange.OrderInfo, 0, len(m.orders))
+	for _, o := range m.orders {
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) ClosedOrders(symbol string, since time.Time) ([]exchange.OrderInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []exchange.OrderInfo
+	for _, o := range m.orders {
+		if o.TradePair != symbol {
+			continue
+		}
+		switch o.Status {
+		case exchange.StatusCompleted, exchange.StatusCancelled:
+		default:
+			continue
+		}
+		if o.Accepted != nil && o.Accepted.Before(since) {
+			continue
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Trades(symbol string, since time.Time) ([]exchange.Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []exchange.Trade
+	for _, t := range m.trades[symbol] {
+		if t.CreatedAt.Before(since) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (m *MemoryStore) Close() error { return nil }
+
+// rehydrate seeds Tracker with Store's still-open orders, then
+// reconciles against GetOrderByStatus for every symbol so orders placed
+// in a prior process are not lost. It is a no-op if Store or Tracker is
+// unset.
+//
+// Orders already in a terminal status are not replayed: Tracker.NewOrder
+// always enters StatusSubmitted, so feeding it closed orders would wrongly
+// resurface them in Tracker.Executed() and cause CancelAll/CancelMarket to
+// re-issue CancelOrder against an exchange order that's already done.
+func (c *Client) rehydrate() {
+	if c.Store == nil || c.Tracker == nil {
+		return
+	}
+
+	orders, err := c.Store.Orders()
+	if err != nil {
+		c.logger().Errorf("c2cx: rehydrate failed", "error", err)
+		return
+	}
+	var seeded int
+	for _, o := range orders {
+		switch o.Status {
+		case exchange.StatusCompleted, exchange.StatusCancelled:
+			continue
+		}
+		c.Tracker.NewOrder(o.TradePair, o.Type, o.Status, o.OrderID, o.Amount, o.Price)
+		seeded++
+	}
+	c.logger().Debugf("c2cx: rehydrated orders from store", "count", seeded, "total", len(orders))
+
+	for _, sym := range allowed {
+		if err := c.checkUpdate(sym); err != nil {
+			c.logger().Warnf("c2cx: reconcile failed", "symbol", sym, "error", err)
+		}
+	}
+}
+
+// persistTrade records orderID's fill as an executed trade in Store,
+// it is a no-op if Store or Tracker is unset
+func (c *Client) persistTrade(symbol string, orderID int) {
+	if c.Store == nil || c.Tracker == nil {
+		return
+	}
+	order, err := c.Tracker.Get(orderID)
+	if err != nil {
+		c.logger().Errorf("c2cx: persist trade failed", "orderID", orderID, "error", err)
+		return
+	}
+	trade := exchange.Trade{
+		TradeID:   orderID,
+		Price:     order.Price,
+		Amount:    order.Amount,
+		CreatedAt: time.Now(),
+	}
+	if err := c.Store.SaveTrade(symbol, trade); err != nil {
+		c.logger().Errorf("c2cx: persist trade failed", "orderID", orderID, "error", err)
+	}
+}
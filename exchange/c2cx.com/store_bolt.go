@@ -0,0 +1,147 @@
+package c2cx
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+	"github.com/uberfurrer/tradebot/exchange"
+)
+
+var (
+	boltOrdersBucket = []byte("orders")
+	boltTradesBucket = []byte("trades")
+)
+
+// BoltStore persists orders and trades to a local BoltDB file, so
+// Tracker state survives a process restart without an external
+// database
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a BoltDB file at path
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "c2cx: open bolt store")
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(boltOrdersBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(boltTradesBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "c2cx: init bolt store")
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (b *BoltStore) SaveOrder(order exchange.OrderInfo) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(order)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(boltOrdersBucket).Put(orderKey(order.OrderID), data)
+	})
+}
+
+func (b *BoltStore) UpdateOrderStatus(order exchange.OrderInfo) error {
+	return b.SaveOrder(order)
+}
+
+func (b *BoltStore) SaveTrade(symbol string, trade exchange.Trade) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		data, err := json.Marshal(trade)
+		if err != nil {
+			return err
+		}
+		return tx.Bucket(boltTradesBucket).Put(tradeKey(symbol, trade), data)
+	})
+}
+
+func (b *BoltStore) Orders() ([]exchange.OrderInfo, error) {
+	var out []exchange.OrderInfo
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltOrdersBucket).ForEach(func(k, v []byte) error {
+			var order exchange.OrderInfo
+			if err := json.Unmarshal(v, &order); err != nil {
+				return err
+			}
+			out = append(out, order)
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (b *BoltStore) ClosedOrders(symbol string, since time.Time) ([]exchange.OrderInfo, error) {
+	orders, err := b.Orders()
+	if err != nil {
+		return nil, err
+	}
+	var out []exchange.OrderInfo
+	for _, o := range orders {
+		if o.TradePair != symbol {
+			continue
+		}
+		switch o.Status {
+		case exchange.StatusCompleted, exchange.StatusCancelled:
+		default:
+			continue
+		}
+		if o.Accepted != nil && o.Accepted.Before(since) {
+			continue
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+func (b *BoltStore) Trades(symbol string, since time.Time) ([]exchange.Trade, error) {
+	var out []exchange.Trade
+	prefix := []byte(symbol + "/")
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltTradesBucket).Cursor()
+		for k, v := c.Seek(prefix); k != nil && hasPrefix(k, prefix); k, v = c.Next() {
+			var trade exchange.Trade
+			if err := json.Unmarshal(v, &trade); err != nil {
+				return err
+			}
+			if trade.CreatedAt.Before(since) {
+				continue
+			}
+			out = append(out, trade)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func (b *BoltStore) Close() error { return b.db.Close() }
+
+func orderKey(orderID int) []byte {
+	return []byte(strconv.Itoa(orderID))
+}
+
+func tradeKey(symbol string, trade exchange.Trade) []byte {
+	return []byte(symbol + "/" + trade.CreatedAt.Format(time.RFC3339Nano) + "/" + strconv.Itoa(trade.TradeID))
+}
+
+func hasPrefix(b, prefix []byte) bool {
+	if len(b) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if b[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
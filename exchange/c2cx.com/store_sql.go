@@ -0,0 +1,147 @@
+package c2cx
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uberfurrer/tradebot/exchange"
+)
+
+// SQLStore persists orders and trades to a database/sql-compatible
+// database. The schema is created on first use with ANSI-SQL types and
+// queries use "?" positional placeholders, matching drivers such as
+// sqlite3 and mysql; a Postgres driver (lib/pq, pgx) requires "$1, $2, ..."
+// placeholders and is not supported as-is. Callers are expected to
+// import the driver package for side effects before constructing Client.
+type SQLStore struct {
+	db *sql.DB
+}
+
+// NewSQLStore prepares db for use as a Store, creating its tables if
+// they do not already exist
+func NewSQLStore(db *sql.DB) (*SQLStore, error) {
+	s := &SQLStore{db: db}
+	if err := s.migrate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQLStore) migrate() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS c2cx_orders (
+			order_id INTEGER PRIMARY KEY,
+			symbol   VARCHAR(32) NOT NULL,
+			side     VARCHAR(8) NOT NULL,
+			status   VARCHAR(16) NOT NULL,
+			amount   DOUBLE PRECISION NOT NULL,
+			price    DOUBLE PRECISION NOT NULL,
+			accepted TIMESTAMP NULL
+		)`)
+	if err != nil {
+		return errors.Wrap(err, "c2cx: migrate c2cx_orders")
+	}
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS c2cx_trades (
+			trade_id   INTEGER PRIMARY KEY,
+			symbol     VARCHAR(32) NOT NULL,
+			price      DOUBLE PRECISION NOT NULL,
+			amount     DOUBLE PRECISION NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`)
+	if err != nil {
+		return errors.Wrap(err, "c2cx: migrate c2cx_trades")
+	}
+	return nil
+}
+
+func (s *SQLStore) SaveOrder(order exchange.OrderInfo) error {
+	_, err := s.db.Exec(`
+		INSERT INTO c2cx_orders (order_id, symbol, side, status, amount, price, accepted)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		order.OrderID, order.TradePair, order.Type, order.Status, order.Amount, order.Price, order.Accepted)
+	return errors.Wrap(err, "c2cx: save order")
+}
+
+func (s *SQLStore) UpdateOrderStatus(order exchange.OrderInfo) error {
+	_, err := s.db.Exec(`
+		UPDATE c2cx_orders SET status = ?, amount = ?, price = ?, accepted = ?
+		WHERE order_id = ?`,
+		order.Status, order.Amount, order.Price, order.Accepted, order.OrderID)
+	return errors.Wrap(err, "c2cx: update order status")
+}
+
+func (s *SQLStore) SaveTrade(symbol string, trade exchange.Trade) error {
+	_, err := s.db.Exec(`
+		INSERT INTO c2cx_trades (trade_id, symbol, price, amount, created_at)
+		VALUES (?, ?, ?, ?, ?)`,
+		trade.TradeID, symbol, trade.Price, trade.Amount, trade.CreatedAt)
+	return errors.Wrap(err, "c2cx: save trade")
+}
+
+func (s *SQLStore) Orders() ([]exchange.OrderInfo, error) {
+	rows, err := s.db.Query(`SELECT order_id, symbol, side, status, amount, price, accepted FROM c2cx_orders`)
+	if err != nil {
+		return nil, errors.Wrap(err, "c2cx: query orders")
+	}
+	defer rows.Close()
+
+	var out []exchange.OrderInfo
+	for rows.Next() {
+		var o exchange.OrderInfo
+		if err := rows.Scan(&o.OrderID, &o.TradePair, &o.Type, &o.Status, &o.Amount, &o.Price, &o.Accepted); err != nil {
+			return nil, errors.Wrap(err, "c2cx: scan order")
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLStore) ClosedOrders(symbol string, since time.Time) ([]exchange.OrderInfo, error) {
+	// accepted IS NULL is kept regardless of since, matching
+	// MemoryStore/BoltStore, which only filter orders that have an
+	// Accepted time set: SQL NULL comparisons are never true, so
+	// "accepted >= ?" alone would silently drop every NULL-accepted row
+	rows, err := s.db.Query(`
+		SELECT order_id, symbol, side, status, amount, price, accepted
+		FROM c2cx_orders
+		WHERE symbol = ? AND status IN (?, ?) AND (accepted IS NULL OR accepted >= ?)`,
+		symbol, exchange.StatusCompleted, exchange.StatusCancelled, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "c2cx: query closed orders")
+	}
+	defer rows.Close()
+
+	var out []exchange.OrderInfo
+	for rows.Next() {
+		var o exchange.OrderInfo
+		if err := rows.Scan(&o.OrderID, &o.TradePair, &o.Type, &o.Status, &o.Amount, &o.Price, &o.Accepted); err != nil {
+			return nil, errors.Wrap(err, "c2cx: scan order")
+		}
+		out = append(out, o)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLStore) Trades(symbol string, since time.Time) ([]exchange.Trade, error) {
+	rows, err := s.db.Query(`
+		SELECT trade_id, price, amount, created_at FROM c2cx_trades
+		WHERE symbol = ? AND created_at >= ?`, symbol, since)
+	if err != nil {
+		return nil, errors.Wrap(err, "c2cx: query trades")
+	}
+	defer rows.Close()
+
+	var out []exchange.Trade
+	for rows.Next() {
+		var t exchange.Trade
+		if err := rows.Scan(&t.TradeID, &t.Price, &t.Amount, &t.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, "c2cx: scan trade")
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQLStore) Close() error { return s.db.Close() }
@@ -0,0 +1,390 @@
+package c2cx
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+	"github.com/uberfurrer/tradebot/exchange"
+)
+
+// streamURL is c2cx's push feed endpoint
+const streamURL = "wss://api.c2cx.com/ws/v1"
+
+// Stream delivers incremental order book diffs and order/trade updates
+// from c2cx's push feed in real time, reconnecting and resubscribing
+// automatically. While disconnected, it falls back to the same REST
+// polling Client.Update uses, so callers see no gap in coverage.
+type Stream struct {
+	client *Client
+
+	// OnBookSnapshot is called with a full order book replacing any
+	// prior state, on subscribe and after a resync
+	OnBookSnapshot func(symbol string, bids, asks []exchange.MarketOrder)
+	// OnBookUpdate is called with an incremental order book diff
+	OnBookUpdate func(symbol string, bids, asks []exchange.MarketOrder)
+	// OnOrderUpdate is called on every order status transition
+	OnOrderUpdate func(order exchange.OrderInfo)
+	// OnTradeUpdate is called for every trade execution
+	OnTradeUpdate func(symbol string, trade exchange.Trade)
+
+	connected int32 // atomic bool, 1 once the websocket is up and subscribed
+
+	seqMu sync.Mutex
+	seq   map[string]int64
+
+	// bookMu guards books: exchange.OrderBookTracker only exposes
+	// UpdateSym (a full replace), so incremental diffs are merged into
+	// a local per-symbol copy here before being pushed on as a snapshot
+	bookMu sync.Mutex
+	books  map[string]*localBook
+
+	fallbackMu   sync.Mutex
+	fallbackStop chan struct{}
+	fallbackWG   sync.WaitGroup
+
+	connMu sync.Mutex
+	conn   *websocket.Conn
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// streamReadTimeout bounds how long readLoop can block in ReadJSON
+// without a message, so a quiet-but-open socket can't wedge Stop()
+const streamReadTimeout = 60 * time.Second
+
+// NewStream connects to c2cx's push feed and starts delivering updates
+// for every symbol in allowed. Call Stop to tear it down.
+func (c *Client) NewStream() *Stream {
+	// the REST fallback records into c.metrics, which only Update
+	// allocates otherwise
+	c.metricsMu.Lock()
+	if c.metrics == nil {
+		c.metrics = make(map[string]SymbolMetrics, len(allowed))
+	}
+	c.metricsMu.Unlock()
+
+	s := &Stream{
+		client: c,
+		seq:    make(map[string]int64),
+		books:  make(map[string]*localBook),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Stop disconnects the stream and stops any active REST fallback
+func (s *Stream) Stop() {
+	close(s.stop)
+	// unblock a readLoop parked in ReadJSON on an open-but-silent
+	// socket, readLoop's own deadline would otherwise leave Stop
+	// waiting up to streamReadTimeout
+	s.closeConn()
+	<-s.done
+}
+
+func (s *Stream) setConn(conn *websocket.Conn) {
+	s.connMu.Lock()
+	s.conn = conn
+	s.connMu.Unlock()
+}
+
+func (s *Stream) closeConn() {
+	s.connMu.Lock()
+	defer s.connMu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// Connected reports whether the websocket connection is currently up
+// if false, updates are being served by the REST fallback
+func (s *Stream) Connected() bool {
+	return atomic.LoadInt32(&s.connected) == 1
+}
+
+func (s *Stream) run() {
+	defer close(s.done)
+
+	backoff := time.Second
+	for {
+		select {
+		case <-s.stop:
+			s.stopFallback()
+			return
+		default:
+		}
+
+		conn, err := s.connect()
+		if err != nil {
+			s.client.logger().Warnf("c2cx: stream connect failed", "error", err)
+			s.startFallback()
+			select {
+			case <-time.After(backoff):
+			case <-s.stop:
+				s.stopFallback()
+				return
+			}
+			backoff = nextBackoff(backoff, time.Second, s.client.maxBackoff())
+			continue
+		}
+
+		backoff = time.Second
+		s.resync()
+		s.stopFallback()
+		s.setConn(conn)
+		atomic.StoreInt32(&s.connected, 1)
+		s.client.logger().Debugf("c2cx: stream connected")
+
+		s.readLoop(conn)
+
+		atomic.StoreInt32(&s.connected, 0)
+		s.setConn(nil)
+		conn.Close()
+		s.client.logger().Warnf("c2cx: stream disconnected, falling back to polling")
+		s.startFallback()
+	}
+}
+
+func (s *Stream) connect() (*websocket.Conn, error) {
+	conn, _, err := websocket.DefaultDialer.Dial(streamURL, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "c2cx: dial stream")
+	}
+	for _, sym := range allowed {
+		if err := conn.WriteJSON(streamSubscribe{Channel: "orderbook", Symbol: sym}); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "c2cx: subscribe orderbook %s", sym)
+		}
+		if err := conn.WriteJSON(streamSubscribe{Channel: "orders", Symbol: sym}); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "c2cx: subscribe orders %s", sym)
+		}
+	}
+	return conn, nil
+}
+
+// resync fetches a fresh snapshot for every symbol and resets sequence
+// tracking, used on connect and whenever a sequence gap is detected
+func (s *Stream) resync() {
+	s.seqMu.Lock()
+	s.seq = make(map[string]int64, len(allowed))
+	s.seqMu.Unlock()
+
+	if s.client.OrderBookTracker == nil {
+		return
+	}
+	for _, sym := range allowed {
+		orders, err := GetOrderBook(sym)
+		if err != nil {
+			s.client.logger().Warnf("c2cx: stream resync failed", "symbol", sym, "error", err)
+			continue
+		}
+
+		s.bookMu.Lock()
+		book := newLocalBook()
+		book.reset(orders.Bids, orders.Asks)
+		s.books[sym] = book
+		s.bookMu.Unlock()
+
+		s.client.OrderBookTracker.UpdateSym(sym, orders.Bids, orders.Asks)
+		if s.OnBookSnapshot != nil {
+			s.OnBookSnapshot(sym, orders.Bids, orders.Asks)
+		}
+	}
+}
+
+func (s *Stream) readLoop(conn *websocket.Conn) {
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+	})
+	conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+
+	for {
+		var msg streamMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			s.client.logger().Warnf("c2cx: stream read failed", "error", err)
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+
+		switch msg.Channel {
+		case "orderbook":
+			s.handleBookUpdate(msg)
+		case "orders":
+			if msg.Order != nil && s.OnOrderUpdate != nil {
+				s.OnOrderUpdate(*msg.Order)
+			}
+		case "trades":
+			if msg.Trade != nil {
+				if s.client.Store != nil {
+					if err := s.client.Store.SaveTrade(msg.Symbol, *msg.Trade); err != nil {
+						s.client.logger().Errorf("c2cx: persist trade failed", "symbol", msg.Symbol, "error", err)
+					}
+				}
+				if s.OnTradeUpdate != nil {
+					s.OnTradeUpdate(msg.Symbol, *msg.Trade)
+				}
+			}
+		}
+
+		select {
+		case <-s.stop:
+			return
+		default:
+		}
+	}
+}
+
+func (s *Stream) handleBookUpdate(msg streamMessage) {
+	s.seqMu.Lock()
+	last := s.seq[msg.Symbol]
+	gap := last != 0 && msg.Sequence != last+1
+	s.seq[msg.Symbol] = msg.Sequence
+	s.seqMu.Unlock()
+
+	if gap {
+		s.client.logger().Warnf("c2cx: stream sequence gap, resyncing", "symbol", msg.Symbol, "expected", last+1, "got", msg.Sequence)
+		s.resync()
+		return
+	}
+
+	if s.client.OrderBookTracker != nil {
+		s.bookMu.Lock()
+		book, ok := s.books[msg.Symbol]
+		if !ok {
+			book = newLocalBook()
+			s.books[msg.Symbol] = book
+		}
+		book.applyDiff(msg.Bids, msg.Asks)
+		bids, asks := book.snapshot()
+		s.bookMu.Unlock()
+
+		// exchange.OrderBookTracker only exposes UpdateSym, a full
+		// replace, so push the merged snapshot rather than the raw diff
+		s.client.OrderBookTracker.UpdateSym(msg.Symbol, bids, asks)
+	}
+	if s.OnBookUpdate != nil {
+		s.OnBookUpdate(msg.Symbol, msg.Bids, msg.Asks)
+	}
+}
+
+// localBook is a per-symbol order book maintained from incremental
+// diffs, keyed by price with a volume of 0 meaning the level was
+// removed. It exists because exchange.OrderBookTracker only exposes
+// UpdateSym (a full snapshot replace), not an incremental apply.
+type localBook struct {
+	bids map[float64]float64
+	asks map[float64]float64
+}
+
+func newLocalBook() *localBook {
+	return &localBook{
+		bids: make(map[float64]float64),
+		asks: make(map[float64]float64),
+	}
+}
+
+func (b *localBook) reset(bids, asks []exchange.MarketOrder) {
+	b.bids = make(map[float64]float64, len(bids))
+	for _, o := range bids {
+		b.bids[o.Price] = o.Volume
+	}
+	b.asks = make(map[float64]float64, len(asks))
+	for _, o := range asks {
+		b.asks[o.Price] = o.Volume
+	}
+}
+
+func (b *localBook) applyDiff(bids, asks []exchange.MarketOrder) {
+	mergeLevels(b.bids, bids)
+	mergeLevels(b.asks, asks)
+}
+
+func mergeLevels(side map[float64]float64, diff []exchange.MarketOrder) {
+	for _, o := range diff {
+		if o.Volume == 0 {
+			delete(side, o.Price)
+			continue
+		}
+		side[o.Price] = o.Volume
+	}
+}
+
+func (b *localBook) snapshot() (bids, asks []exchange.MarketOrder) {
+	return levelsDesc(b.bids), levelsAsc(b.asks)
+}
+
+func levelsDesc(side map[float64]float64) []exchange.MarketOrder {
+	out := marketOrders(side)
+	sort.Slice(out, func(i, j int) bool { return out[i].Price > out[j].Price })
+	return out
+}
+
+func levelsAsc(side map[float64]float64) []exchange.MarketOrder {
+	out := marketOrders(side)
+	sort.Slice(out, func(i, j int) bool { return out[i].Price < out[j].Price })
+	return out
+}
+
+func marketOrders(side map[float64]float64) []exchange.MarketOrder {
+	out := make([]exchange.MarketOrder, 0, len(side))
+	for price, volume := range side {
+		out = append(out, exchange.MarketOrder{Price: price, Volume: volume})
+	}
+	return out
+}
+
+// startFallback begins REST polling for every symbol, it is a no-op if
+// the fallback is already running
+func (s *Stream) startFallback() {
+	s.fallbackMu.Lock()
+	defer s.fallbackMu.Unlock()
+	if s.fallbackStop != nil {
+		return
+	}
+	s.fallbackStop = make(chan struct{})
+	stop := s.fallbackStop
+	s.fallbackWG.Add(len(allowed))
+	for _, sym := range allowed {
+		go func(symbol string) {
+			defer s.fallbackWG.Done()
+			s.client.pollSymbol(symbol, stop)
+		}(sym)
+	}
+}
+
+func (s *Stream) stopFallback() {
+	s.fallbackMu.Lock()
+	defer s.fallbackMu.Unlock()
+	if s.fallbackStop == nil {
+		return
+	}
+	close(s.fallbackStop)
+	s.fallbackStop = nil
+	s.fallbackWG.Wait()
+}
+
+// streamSubscribe is sent to subscribe to a channel for a symbol
+type streamSubscribe struct {
+	Channel string `json:"channel"`
+	Symbol  string `json:"symbol"`
+}
+
+// streamMessage is a push from c2cx's feed, only the fields relevant to
+// the channel are populated
+type streamMessage struct {
+	Channel  string                 `json:"channel"`
+	Symbol   string                 `json:"symbol"`
+	Sequence int64                  `json:"sequence"`
+	Bids     []exchange.MarketOrder `json:"bids"`
+	Asks     []exchange.MarketOrder `json:"asks"`
+	Order    *exchange.OrderInfo    `json:"order"`
+	Trade    *exchange.Trade        `json:"trade"`
+}
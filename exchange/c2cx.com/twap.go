@@ -0,0 +1,300 @@
+package c2cx
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/uberfurrer/tradebot/exchange"
+	"golang.org/x/time/rate"
+)
+
+// TwapConfig describes a TWAP (time-weighted average price) execution
+type TwapConfig struct {
+	Symbol string
+	Side   string
+
+	// TargetQuantity is the total amount to be executed
+	TargetQuantity float64
+	// SliceQuantity is the upper bound on the size of any single child order
+	SliceQuantity float64
+
+	// Duration is the total time over which TargetQuantity should be worked
+	Duration time.Duration
+	// UpdateInterval is how often the resting child order is repriced
+	UpdateInterval time.Duration
+
+	// TickOffset is added (buy) or subtracted (sell) from the best
+	// bid/ask, in price units, when pegging the child order
+	TickOffset float64
+
+	// PriceLimit is the worst price a child order may be placed at: the
+	// highest price for a buy, the lowest for a sell; 0 disables it
+	PriceLimit float64
+	// StopPrice stops the execution once crossed, 0 disables it
+	StopPrice float64
+
+	// Limiter bounds how often child orders are cancelled/resubmitted,
+	// e.g. rate.NewLimiter(rate.Every(time.Minute), 2) for a "1+1/1m" spec
+	Limiter *rate.Limiter
+}
+
+// TwapExecution slices a parent order into smaller child orders and
+// submits them over Duration, pegging each child's limit price to the
+// current best bid/ask fetched with GetOrderBook on every reprice;
+// Client.OrderBookTracker is not consulted.
+type TwapExecution struct {
+	client *Client
+	cfg    TwapConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu           sync.Mutex
+	filled       float64
+	filledValue  float64
+	childOrderID int
+	err          error
+}
+
+// NewTwapExecution starts slicing and submitting cfg.TargetQuantity of
+// cfg.Symbol over cfg.Duration. Execution stops, and Done() is closed,
+// once the target is filled, StopPrice is crossed, or ctx is cancelled.
+func (c *Client) NewTwapExecution(ctx context.Context, cfg TwapConfig) (*TwapExecution, error) {
+	symbol, err := normalize(cfg.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Symbol = symbol
+
+	if cfg.TargetQuantity <= 0 {
+		return nil, errors.New("c2cx: TargetQuantity must be positive")
+	}
+	if cfg.SliceQuantity <= 0 {
+		return nil, errors.New("c2cx: SliceQuantity must be positive")
+	}
+	if cfg.UpdateInterval <= 0 {
+		return nil, errors.New("c2cx: UpdateInterval must be positive")
+	}
+	if cfg.Limiter == nil {
+		cfg.Limiter = rate.NewLimiter(rate.Every(time.Minute), 2)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	t := &TwapExecution{
+		client: c,
+		cfg:    cfg,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go t.run(ctx)
+	return t, nil
+}
+
+func (t *TwapExecution) run(ctx context.Context) {
+	defer close(t.done)
+	defer t.cancelChild()
+
+	deadline := time.Now().Add(t.cfg.Duration)
+	ticker := time.NewTicker(t.cfg.UpdateInterval)
+	defer ticker.Stop()
+
+	t.reprice(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			t.setErr(ctx.Err())
+			return
+		case now := <-ticker.C:
+			if t.Filled() >= t.cfg.TargetQuantity {
+				return
+			}
+			if now.After(deadline) {
+				return
+			}
+			if t.stopPriceCrossed() {
+				return
+			}
+			t.reprice(ctx)
+		}
+	}
+}
+
+// reprice cancels the resting child order if it is still unfilled and
+// submits a new one sized as the smaller of the remaining target and
+// SliceQuantity. Elapsed and remaining time do not affect the size.
+func (t *TwapExecution) reprice(ctx context.Context) {
+	t.cancelChild()
+
+	remaining := t.cfg.TargetQuantity - t.Filled()
+	if remaining <= 0 {
+		return
+	}
+	qty := remaining
+	if qty > t.cfg.SliceQuantity {
+		qty = t.cfg.SliceQuantity
+	}
+
+	price, err := t.pegPrice()
+	if err != nil {
+		t.setErr(err)
+		return
+	}
+
+	if err := t.cfg.Limiter.Wait(ctx); err != nil {
+		t.setErr(err)
+		return
+	}
+
+	orderID, err := CreateOrder(t.client.Key, t.client.Secret, t.cfg.Symbol, t.cfg.Side, PriceTypeLimit, qty, 1, price, nil, nil, nil)
+	if err != nil {
+		t.setErr(err)
+		return
+	}
+	if t.client.Tracker != nil {
+		action := exchange.ActionBuy
+		if t.cfg.Side == "sell" {
+			action = exchange.ActionSell
+		}
+		t.client.Tracker.NewOrder(t.cfg.Symbol, action, exchange.StatusSubmitted, orderID, qty, price)
+		t.client.persistOrder(orderID)
+	}
+
+	t.mu.Lock()
+	t.childOrderID = orderID
+	t.mu.Unlock()
+}
+
+// pegPrice derives the child limit price from the current best bid/ask,
+// offset by TickOffset and bounded by PriceLimit.
+func (t *TwapExecution) pegPrice() (float64, error) {
+	book, err := GetOrderBook(t.cfg.Symbol)
+	if err != nil {
+		return 0, err
+	}
+	bids, asks := book.Bids, book.Asks
+
+	var price float64
+	switch t.cfg.Side {
+	case "buy":
+		if len(bids) == 0 {
+			return 0, errors.Errorf("c2cx: empty bid book for %s", t.cfg.Symbol)
+		}
+		price = bids[0].Price + t.cfg.TickOffset
+		if t.cfg.PriceLimit > 0 && price > t.cfg.PriceLimit {
+			price = t.cfg.PriceLimit
+		}
+	case "sell":
+		if len(asks) == 0 {
+			return 0, errors.Errorf("c2cx: empty ask book for %s", t.cfg.Symbol)
+		}
+		price = asks[0].Price - t.cfg.TickOffset
+		if t.cfg.PriceLimit > 0 && price < t.cfg.PriceLimit {
+			price = t.cfg.PriceLimit
+		}
+	default:
+		return 0, errors.Errorf("c2cx: unknown side %q", t.cfg.Side)
+	}
+	return price, nil
+}
+
+func (t *TwapExecution) stopPriceCrossed() bool {
+	if t.cfg.StopPrice == 0 {
+		return false
+	}
+	book, err := GetOrderBook(t.cfg.Symbol)
+	if err != nil {
+		return false
+	}
+	bids, asks := book.Bids, book.Asks
+	switch t.cfg.Side {
+	case "buy":
+		return len(asks) > 0 && asks[0].Price >= t.cfg.StopPrice
+	case "sell":
+		return len(bids) > 0 && bids[0].Price <= t.cfg.StopPrice
+	}
+	return false
+}
+
+func (t *TwapExecution) cancelChild() {
+	t.mu.Lock()
+	orderID := t.childOrderID
+	t.childOrderID = 0
+	t.mu.Unlock()
+	if orderID == 0 {
+		return
+	}
+
+	// cancelChild is invoked to clear a resting order before repricing
+	// or on shutdown, so only a StatusCompleted order actually filled;
+	// Amount is the originally submitted size, not a fill quantity, and
+	// is >0 for every order regardless of outcome
+	if t.client.Tracker != nil {
+		if order, err := t.client.Tracker.Get(orderID); err == nil {
+			switch order.Status {
+			case exchange.StatusCompleted:
+				t.mu.Lock()
+				t.filled += order.Amount
+				t.filledValue += order.Amount * order.Price
+				t.mu.Unlock()
+				// already filled on the exchange, cancelling it would be
+				// spurious and would overwrite Tracker's StatusCompleted
+				// with StatusCancelled
+				return
+			case exchange.StatusCancelled:
+				// already cancelled, nothing left to do
+				return
+			}
+		}
+	}
+
+	if err := CancelOrder(t.client.Key, t.client.Secret, orderID); err != nil {
+		t.setErr(err)
+		return
+	}
+	if t.client.Tracker != nil {
+		t.client.Tracker.Cancel(orderID)
+	}
+}
+
+func (t *TwapExecution) setErr(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// Done returns a channel that is closed once the execution has stopped,
+// either because TargetQuantity was reached, StopPrice was crossed,
+// Duration elapsed or ctx was cancelled.
+func (t *TwapExecution) Done() <-chan struct{} { return t.done }
+
+// Cancel stops the execution and cancels any resting child order.
+func (t *TwapExecution) Cancel() { t.cancel() }
+
+// Filled returns the cumulative filled quantity so far.
+func (t *TwapExecution) Filled() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.filled
+}
+
+// AveragePrice returns the quantity-weighted average fill price, or 0 if
+// nothing has filled yet.
+func (t *TwapExecution) AveragePrice() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.filled == 0 {
+		return 0
+	}
+	return t.filledValue / t.filled
+}
+
+// Err returns the first error encountered during execution, if any.
+func (t *TwapExecution) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}